@@ -0,0 +1,109 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package unittest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/execution/test/unit/unittest/fixture"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/execution/test/unit/unittest/stagegraph"
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+const (
+	manifestPath = "./manifest.yaml"
+	projectID    = "dummy-project-id"
+	network      = "projects/dummy-project/global/networks/dummy-vpc-network01"
+	psaRange     = "dummy-psa-range"
+)
+
+// alloyDBConfig mirrors the subset of the AlloyDB producer's YAML input
+// schema this test needs, matching execution/test/unit/producer/AlloyDB.
+type alloyDBConfig struct {
+	ProjectID     string `yaml:"project_id"`
+	ClusterID     string `yaml:"cluster_id"`
+	NetworkConfig struct {
+		Network  string `yaml:"network"`
+		PSARange string `yaml:"psa_range"`
+	} `yaml:"network_config"`
+}
+
+// stageVars returns the tfvars each stage in manifest.yaml actually
+// requires to plan, since the stages in this DAG are heterogeneous (a
+// networking stage, a security stage, an AlloyDB producer, a consumer) and
+// none of them share a single generic var set.
+func stageVars(t *testing.T, stage string) map[string]any {
+	t.Helper()
+
+	switch stage {
+	case "networking":
+		return map[string]any{"project_id": projectID}
+	case "security":
+		return map[string]any{"project_id": projectID, "network": network}
+	case "producer-alloydb":
+		cfg := alloyDBConfig{ProjectID: projectID, ClusterID: "dummy"}
+		cfg.NetworkConfig.Network = network
+		cfg.NetworkConfig.PSARange = psaRange
+		return map[string]any{"config_folder_path": fixture.WriteConfigFolder(t, cfg)}
+	case "consumer":
+		// alloydb_cluster_connection_name is normally wired from the
+		// producer-alloydb stage's output and has no default, so it must be
+		// supplied here for the consumer stage to plan at all.
+		return map[string]any{
+			"project_id":                      projectID,
+			"alloydb_cluster_connection_name": "dummy-project-id:us-central1:dummy",
+		}
+	default:
+		t.Fatalf("stageVars: no tfvars defined for stage %q", stage)
+		return nil
+	}
+}
+
+/*
+TestStageOutputsFeedDownstreamVariables plans every stage in manifest.yaml
+(02-networking -> 03-security -> 04-producer/AlloyDB -> 06-consumer), each
+with the tfvars that stage actually requires, and checks, per
+stagegraph.Binding, that an upstream stage's output still exists and still
+feeds the downstream variable the manifest says it should. This catches
+drift where a producer renames an output but a consumer still references
+the old name.
+*/
+func TestStageOutputsFeedDownstreamVariables(t *testing.T) {
+	manifest, err := stagegraph.LoadManifest(manifestPath)
+	if err != nil {
+		t.Fatalf("failed to load stage manifest: %v", err)
+	}
+
+	plans := make(map[string]*tfjson.Plan, len(manifest.Stages))
+	for _, stage := range manifest.Stages {
+		terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
+			TerraformDir: stage.TerraformDir,
+			Vars:         stageVars(t, stage.Name),
+			Reconfigure:  true,
+			Lock:         true,
+			PlanFilePath: fmt.Sprintf("./plan-%s", stage.Name),
+			NoColor:      true,
+		})
+		planStruct := terraform.InitAndPlanAndShow(t, terraformOptions)
+		content, err := terraform.ParsePlanJSON(planStruct)
+		if err != nil {
+			t.Fatalf("failed to parse plan JSON for stage %q: %v", stage.Name, err)
+		}
+		plans[stage.Name] = content
+	}
+
+	stagegraph.Validate(t, plans, manifest)
+}
@@ -15,8 +15,18 @@ package unittest
 
 import (
 	compare "cmp"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/execution/test/unit/unittest/fixture"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/execution/test/unit/unittest/junit"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/execution/test/unit/unittest/live"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/execution/test/unit/unittest/planassert"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/execution/test/unit/unittest/planrun"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/gruntwork-io/terratest/modules/terraform"
@@ -41,28 +51,92 @@ var (
 	}
 )
 
+// suite runs terraform init once against terraformDirectoryPath with a
+// shared plugin cache, then hands each plan-only test its own temp-dir copy
+// of the module so they can plan in parallel without colliding on
+// PlanFilePath or .terraform.
+var suite *planrun.Suite
+
+// TestMain initializes suite once for the whole package, then flushes any
+// test cases recorded via junit.Track to CNCS_JUNIT_OUT once every test has
+// run.
+func TestMain(m *testing.M) {
+	var err error
+	suite, err = planrun.NewSuite(terraformDirectoryPath)
+	if err != nil {
+		panic(err)
+	}
+	suite.InitOnce()
+
+	code := m.Run()
+	if err := junit.Flush("producer/AlloyDB"); err != nil {
+		panic(err)
+	}
+	os.Exit(code)
+}
+
+// alloyDBConfig mirrors the subset of the AlloyDB producer's YAML input schema
+// exercised by the inline permutation tests below. It only needs the fields
+// those tests set; fixture.WriteConfigFolder marshals it as-is.
+type alloyDBConfig struct {
+	ProjectID     string `yaml:"project_id"`
+	ClusterID     string `yaml:"cluster_id"`
+	NetworkConfig struct {
+		Network  string `yaml:"network"`
+		PSARange string `yaml:"psa_range"`
+	} `yaml:"network_config"`
+	AutomatedBackupPolicy struct {
+		Enabled bool `yaml:"enabled"`
+	} `yaml:"automated_backup_policy"`
+}
+
+func baseAlloyDBConfig() alloyDBConfig {
+	cfg := alloyDBConfig{
+		ProjectID: projectID,
+		ClusterID: "dummy",
+	}
+	cfg.NetworkConfig.Network = network
+	cfg.AutomatedBackupPolicy.Enabled = true
+	return cfg
+}
+
+/*
+TestInitAndPlanRunWithInlineTfVars performs the same sanity check as
+TestInitAndPlanRunWithTfVars, but builds its config_folder_path fixture
+inline via fixture.WriteConfigFolder instead of pointing at the checked-in
+config/ directory. This is the pattern table-driven permutation tests (read
+pools, PSC vs PSA, CMEK on/off, automated backup variants) should follow so
+they don't require a new YAML file per case.
+*/
+func TestInitAndPlanRunWithInlineTfVars(t *testing.T) {
+	t.Parallel()
+	configDir := fixture.WriteConfigFolder(t, baseAlloyDBConfig())
+	terraformOptions, planOutput := suite.Options(t, map[string]any{
+		"config_folder_path": configDir,
+	})
+	junit.Track(t, "TestInitAndPlanRunWithInlineTfVars", planOutput.String)
+	planExitCode := terraform.InitAndPlanWithExitCode(t, terraformOptions)
+	want := 2
+	got := planExitCode
+	if got != want {
+		t.Errorf("Test Plan Exit Code = %v, want = %v", got, want)
+	}
+}
+
 /*
 	TestInitAndPlanRunWithTfVars performs sanity check to ensure the terraform init
 
 && terraform plan is executed successfully and returns a valid Succeeded run code.
 */
 func TestInitAndPlanRunWithTfVars(t *testing.T) {
+	t.Parallel()
+	terraformOptions, planOutput := suite.Options(t, tfVars)
+	junit.Track(t, "TestInitAndPlanRunWithTfVars", planOutput.String)
 	/*
 	 0 = Succeeded with empty diff (no changes)
 	 1 = Error
 	 2 = Succeeded with non-empty diff (changes present)
 	*/
-	// Construct the terraform options with default retryable errors to handle the most common
-	// retryable errors in terraform testing.
-	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		// Set the path to the Terraform code that will be tested.
-		TerraformDir: terraformDirectoryPath,
-		Vars:         tfVars,
-		Reconfigure:  true,
-		Lock:         true,
-		PlanFilePath: "./plan",
-		NoColor:      true,
-	})
 	planExitCode := terraform.InitAndPlanWithExitCode(t, terraformOptions)
 	want := 2
 	got := planExitCode
@@ -76,23 +150,14 @@ TestInitAndPlanRunWithInvalidTfVarsExpectFailureScenario performs test runs with
 to ensure the terraform init && terraform plan is executed unsuccessfully and returns an expected error run code.
 */
 func TestInitAndPlanRunWithInvalidTfVarsExpectFailureScenario(t *testing.T) {
+	t.Parallel()
+	terraformOptions, planOutput := suite.Options(t, invalidTFVars)
+	junit.Track(t, "TestInitAndPlanRunWithInvalidTfVarsExpectFailureScenario", planOutput.String)
 	/*
 	 0 = Succeeded with empty diff (no changes)
 	 1 = Error
 	 2 = Succeeded with non-empty diff (changes present)
 	*/
-	// Construct the terraform options with default retryable errors to handle the most common
-	// retryable errors in terraform testing.
-
-	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		// Set the path to the Terraform code that will be tested.
-		TerraformDir: terraformDirectoryPath,
-		Vars:         invalidTFVars,
-		Reconfigure:  true,
-		Lock:         true,
-		PlanFilePath: "./plan",
-		NoColor:      true,
-	})
 	planExitCode := terraform.InitAndPlanWithExitCode(t, terraformOptions)
 	want := 1
 	got := planExitCode
@@ -107,17 +172,9 @@ func TestInitAndPlanRunWithInvalidTfVarsExpectFailureScenario(t *testing.T) {
 updated.
 */
 func TestResourcesCount(t *testing.T) {
-	// Construct the terraform options with default retryable errors to handle the most common
-	// retryable errors in terraform testing.
-	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		// Set the path to the Terraform code that will be tested.
-		TerraformDir: terraformDirectoryPath,
-		Vars:         tfVars,
-		Reconfigure:  true,
-		Lock:         true,
-		PlanFilePath: "./plan",
-		NoColor:      true,
-	})
+	t.Parallel()
+	terraformOptions, planOutput := suite.Options(t, tfVars)
+	junit.Track(t, "TestResourcesCount", planOutput.String)
 	planStruct := terraform.InitAndPlan(t, terraformOptions)
 	resourceCount := terraform.GetResourceCount(t, planStruct)
 	if got, want := resourceCount.Add, 2; got != want {
@@ -137,18 +194,10 @@ func TestResourcesCount(t *testing.T) {
 created by the terraform solution.
 */
 func TestTerraformModuleResourceAddressListMatch(t *testing.T) {
-	// Construct the terraform options with default retryable errors to handle the most common
-	// retryable errors in terraform testing.
+	t.Parallel()
+	terraformOptions, planOutput := suite.Options(t, tfVars)
+	junit.Track(t, "TestTerraformModuleResourceAddressListMatch", planOutput.String)
 	expectedModulesAddress := []string{"module.alloy_db[\"dummy\"]"}
-	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		// Set the path to the Terraform code that will be tested.
-		TerraformDir: terraformDirectoryPath,
-		Vars:         tfVars,
-		Reconfigure:  true,
-		Lock:         true,
-		PlanFilePath: "./plan",
-		NoColor:      true,
-	})
 	planStruct := terraform.InitAndPlanAndShow(t, terraformOptions)
 	content, err := terraform.ParsePlanJSON(planStruct)
 	if err != nil {
@@ -165,4 +214,79 @@ func TestTerraformModuleResourceAddressListMatch(t *testing.T) {
 	if !cmp.Equal(got, want, cmpopts.SortSlices(compare.Less[string])) {
 		t.Errorf("Test Element Mismatch = %v, want = %v", got, want)
 	}
+
+	// Per-resource attribute assertions, authored alongside the module-address
+	// list check above, so drift in individual planned attributes is caught
+	// without growing the module-address list into a de-facto schema check.
+	planassert.Assert(t, content, []planassert.Rule{
+		{
+			ResourceAddress: `module.alloy_db["dummy"].google_alloydb_cluster.primary`,
+			JSONPath:        "network",
+			Matcher:         planassert.Matcher{Equals: network},
+		},
+		{
+			ResourceAddress: `module.alloy_db["dummy"].google_alloydb_cluster.primary`,
+			JSONPath:        "initial_user.0.password",
+			Matcher:         planassert.Matcher{IsSensitive: true},
+		},
+		{
+			ResourceAddress: `module.alloy_db["dummy"].google_alloydb_cluster.primary`,
+			JSONPath:        "automated_backup_policy.0.enabled",
+			Matcher:         planassert.Matcher{Equals: true},
+		},
+	})
+}
+
+/*
+TestLiveApplyAndTeardown provisions a real AlloyDB cluster end to end. It
+only runs when live mode is enabled (CNCS_LIVE=1): it creates an ephemeral
+project and its prerequisite VPC/PSA range, applies this module against
+them, waits for the cluster to reach READY, and always tears everything
+down via t.Cleanup.
+*/
+func TestLiveApplyAndTeardown(t *testing.T) {
+	if !live.Enabled() {
+		t.Skip("set CNCS_LIVE=1 to run live AlloyDB apply/teardown")
+	}
+
+	project := live.NewProject(t)
+	net := live.ProvisionNetwork(t, project)
+	configDir := fixture.WriteConfigFolder(t, alloyDBConfigFor(project.ID, net))
+
+	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
+		TerraformDir: terraformDirectoryPath,
+		Vars: map[string]any{
+			"config_folder_path": configDir,
+		},
+		Reconfigure: true,
+		Lock:        true,
+		NoColor:     true,
+	})
+	live.Apply(t, terraformOptions)
+
+	live.AssertEventually(t, "AlloyDB cluster reaches READY", 30, 20*time.Second, func() error {
+		return assertClusterReady(project.ID)
+	})
+}
+
+func alloyDBConfigFor(projectID string, net *live.Network) alloyDBConfig {
+	cfg := baseAlloyDBConfig()
+	cfg.ProjectID = projectID
+	cfg.NetworkConfig.Network = net.SelfLink
+	cfg.NetworkConfig.PSARange = net.PSARangeName
+	return cfg
+}
+
+// assertClusterReady returns nil once the live "dummy" cluster's state is
+// READY, and an error describing the current state otherwise.
+func assertClusterReady(projectID string) error {
+	out, err := exec.Command("gcloud", "alloydb", "clusters", "describe", "dummy",
+		"--project", projectID, "--region", "us-central1", "--format=value(state)").Output()
+	if err != nil {
+		return err
+	}
+	if state := strings.TrimSpace(string(out)); state != "READY" {
+		return fmt.Errorf("cluster state = %q, want READY", state)
+	}
+	return nil
 }
@@ -0,0 +1,50 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fixture builds producer `config_folder_path` fixtures on the fly
+// from Go values instead of checking in YAML files under test/unit/.../config.
+// It lets table-driven tests construct many small permutations of a producer
+// input (read pools, PSC vs PSA, CMEK on/off, ...) without growing the repo's
+// fixture directories.
+package fixture
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// WriteConfigFolder marshals each of configs to its own YAML file inside a
+// fresh temporary directory and returns that directory's path, ready to be
+// used as a producer's `config_folder_path` tfvar. The directory is removed
+// automatically when t's test completes.
+func WriteConfigFolder(t *testing.T, configs ...any) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for i, config := range configs {
+		data, err := yaml.Marshal(config)
+		if err != nil {
+			t.Fatalf("fixture: failed to marshal config %d: %v", i, err)
+		}
+		path := filepath.Join(dir, fmt.Sprintf("config-%02d.yaml", i))
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatalf("fixture: failed to write %s: %v", path, err)
+		}
+	}
+	return dir
+}
@@ -0,0 +1,116 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package junit is an opt-in JUnit XML reporter for producer unit tests. It
+// is disabled unless CNCS_JUNIT_OUT is set, so it adds no overhead to a
+// plain `go test` run; CI systems (Cloud Build, Jenkins, GitHub Actions) set
+// the env var to get a JUnit XML artifact. Each test binary writes its own
+// testsuite and replaces whatever file is already at that path, so results
+// from several producer packages are not merged into one file.
+package junit
+
+import (
+	"encoding/xml"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+// EnvVar is the environment variable that enables reporting and names the
+// output file, e.g. CNCS_JUNIT_OUT=report.xml go test ./...
+const EnvVar = "CNCS_JUNIT_OUT"
+
+type suite struct {
+	XMLName  xml.Name   `xml:"testsuite"`
+	Name     string     `xml:"name,attr"`
+	Tests    int        `xml:"tests,attr"`
+	Failures int        `xml:"failures,attr"`
+	Time     float64    `xml:"time,attr"`
+	Cases    []testCase `xml:"testcase"`
+}
+
+type testCase struct {
+	Name      string   `xml:"name,attr"`
+	ClassName string   `xml:"classname,attr"`
+	Time      float64  `xml:"time,attr"`
+	Failure   *failure `xml:"failure,omitempty"`
+}
+
+type failure struct {
+	Message string `xml:"message,attr"`
+	Output  string `xml:",chardata"`
+}
+
+var (
+	mu    sync.Mutex
+	cases []testCase
+)
+
+// Track records the outcome of the currently running test under name once it
+// completes, for later export via Flush. It is a no-op unless EnvVar is set.
+// output, if non-nil, is called on failure to capture the terraform plan
+// output (e.g. from planrun.Suite.Options' returned buffer) into the JUnit
+// failure body.
+func Track(t *testing.T, name string, output func() string) {
+	if os.Getenv(EnvVar) == "" {
+		return
+	}
+	t.Helper()
+	start := time.Now()
+	t.Cleanup(func() {
+		tc := testCase{
+			Name:      name,
+			ClassName: "producer",
+			Time:      time.Since(start).Seconds(),
+		}
+		if t.Failed() {
+			tc.Failure = &failure{Message: "test failed"}
+			if output != nil {
+				tc.Failure.Output = output()
+			}
+		}
+		mu.Lock()
+		cases = append(cases, tc)
+		mu.Unlock()
+	})
+}
+
+// Flush writes every case recorded via Track to the path named by EnvVar, as
+// a single JUnit testsuite named suiteName, replacing any existing file at
+// that path. It is a no-op unless EnvVar is set, and should be called from
+// TestMain after m.Run().
+func Flush(suiteName string) error {
+	path := os.Getenv(EnvVar)
+	if path == "" {
+		return nil
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	s := suite{Name: suiteName, Tests: len(cases), Cases: cases}
+	for _, tc := range cases {
+		s.Time += tc.Time
+		if tc.Failure != nil {
+			s.Failures++
+		}
+	}
+
+	data, err := xml.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append([]byte(xml.Header), data...), 0o644)
+}
@@ -0,0 +1,167 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package live centralizes the plumbing needed to run a producer test
+// against a real GCP project instead of a plan-only dummy project: creating
+// and tearing down an ephemeral project, provisioning the prerequisite VPC
+// and PSA range, and running terraform apply/destroy with retries. It is
+// gated behind CNCS_LIVE=1 so the default `go test` run never touches real
+// infrastructure; every producer (AlloyDB, CloudSQL, GCE, MRC, VectorSearch)
+// is expected to adopt it the same way rather than hand-rolling its own
+// project lifecycle.
+package live
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/gcp"
+	"github.com/gruntwork-io/terratest/modules/random"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/shell"
+	"github.com/gruntwork-io/terratest/modules/terraform"
+)
+
+// EnvVar gates live mode. It is off by default; set it to "1" to opt a test
+// run into creating and tearing down real GCP resources.
+const EnvVar = "CNCS_LIVE"
+
+// Enabled reports whether live mode is turned on for this test run.
+func Enabled() bool {
+	return os.Getenv(EnvVar) == "1"
+}
+
+// Project is an ephemeral GCP project created for the lifetime of a single
+// test. Its t.Cleanup deletes the project, so callers don't need to manage
+// teardown themselves.
+type Project struct {
+	ID string
+}
+
+// NewProject creates a fresh GCP project under TEST_ORG_ID, linked to
+// TEST_BILLING_ACCOUNT, and registers its deletion via t.Cleanup. It skips
+// the test with a clear message if either env var is unset.
+func NewProject(t *testing.T) *Project {
+	t.Helper()
+
+	orgID := os.Getenv("TEST_ORG_ID")
+	billingAccount := os.Getenv("TEST_BILLING_ACCOUNT")
+	if orgID == "" || billingAccount == "" {
+		t.Skip("live: TEST_ORG_ID and TEST_BILLING_ACCOUNT must be set in live mode")
+	}
+
+	projectID := fmt.Sprintf("cncs-live-%s", random.UniqueId())
+	shell.RunCommand(t, shell.Command{
+		Command: "gcloud",
+		Args:    []string{"projects", "create", projectID, "--organization", orgID, "--no-user-output-enabled"},
+	})
+
+	// Register teardown immediately: shell.RunCommand calls t.FailNow on a
+	// non-zero exit, so any later fallible step (billing linking, etc.)
+	// would otherwise skip this and leak the project.
+	t.Cleanup(func() {
+		shell.RunCommand(t, shell.Command{
+			Command: "gcloud",
+			Args:    []string{"projects", "delete", projectID, "--quiet"},
+		})
+	})
+
+	shell.RunCommand(t, shell.Command{
+		Command: "gcloud",
+		Args:    []string{"billing", "projects", "link", projectID, "--billing-account", billingAccount},
+	})
+
+	return &Project{ID: projectID}
+}
+
+// Network is the set of prerequisite networking resources a producer needs
+// before it can create a PSA-attached resource like an AlloyDB cluster.
+type Network struct {
+	SelfLink     string
+	PSARangeName string
+}
+
+// ProvisionNetwork creates a VPC, reserves a PSA range inside it, and
+// establishes the private-services VPC peering to servicenetworking.googleapis.com
+// that the range is reserved for. A producer can't create a PSA-attached
+// resource like an AlloyDB cluster against this network until all three
+// exist, so ProvisionNetwork doesn't return until they do. It retries on the
+// eventual-consistency errors the Compute and Service Networking APIs
+// commonly return right after project creation.
+func ProvisionNetwork(t *testing.T, project *Project) *Network {
+	t.Helper()
+
+	vpcName := fmt.Sprintf("cncs-live-vpc-%s", random.UniqueId())
+	retry.DoWithRetry(t, "create VPC", 5, 10*time.Second, func() (string, error) {
+		return "", gcp.CreateCustomNetwork(t, project.ID, vpcName)
+	})
+
+	rangeName := fmt.Sprintf("cncs-live-psa-%s", random.UniqueId())
+	retry.DoWithRetry(t, "reserve PSA range", 5, 10*time.Second, func() (string, error) {
+		_, err := shell.RunCommandAndGetOutputE(t, shell.Command{
+			Command: "gcloud",
+			Args: []string{
+				"compute", "addresses", "create", rangeName,
+				"--global", "--purpose=VPC_PEERING", "--prefix-length=16",
+				"--network", vpcName, "--project", project.ID,
+			},
+		})
+		return "", err
+	})
+
+	retry.DoWithRetry(t, "connect PSA VPC peering", 5, 10*time.Second, func() (string, error) {
+		_, err := shell.RunCommandAndGetOutputE(t, shell.Command{
+			Command: "gcloud",
+			Args: []string{
+				"services", "vpc-peerings", "connect",
+				"--service=servicenetworking.googleapis.com",
+				"--ranges", rangeName,
+				"--network", vpcName, "--project", project.ID,
+			},
+		})
+		return "", err
+	})
+
+	return &Network{
+		SelfLink:     fmt.Sprintf("projects/%s/global/networks/%s", project.ID, vpcName),
+		PSARangeName: rangeName,
+	}
+}
+
+// AssertEventually polls check until it returns nil, retries times, sleeping
+// between attempts. Producer live tests use it for post-apply assertions
+// that take a moment to settle, e.g. an AlloyDB cluster reaching READY or
+// its primary instance becoming reachable.
+func AssertEventually(t *testing.T, description string, retries int, sleep time.Duration, check func() error) {
+	t.Helper()
+	_, err := retry.DoWithRetryE(t, description, retries, sleep, func() (string, error) {
+		return "", check()
+	})
+	if err != nil {
+		t.Errorf("live: %s: %v", description, err)
+	}
+}
+
+// Apply runs terraform init/apply against opts and registers terraform
+// destroy via t.Cleanup so the resources are torn down however the test
+// exits, including on failure.
+func Apply(t *testing.T, opts *terraform.Options) {
+	t.Helper()
+	t.Cleanup(func() {
+		terraform.Destroy(t, opts)
+	})
+	terraform.InitAndApply(t, opts)
+}
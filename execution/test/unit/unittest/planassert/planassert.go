@@ -0,0 +1,153 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package planassert is a small assertion library for terraform-json Plan
+// structs. It lets producer unit tests declare expectations about individual
+// planned resource attributes (e.g. that a field equals a constant, or that
+// it is marked sensitive) instead of hand-rolling a cmp.Equal comparison per
+// attribute in every test file.
+package planassert
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// TestingT is the subset of *testing.T that Assert needs. Tests pass *testing.T
+// directly; the interface only exists so this package doesn't import "testing"
+// into non-test builds.
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...any)
+}
+
+// Matcher describes how a single planned attribute value is expected to look.
+// Exactly one field should be set; Equals is the zero value default.
+type Matcher struct {
+	// Equals compares the attribute value with cmp.Equal.
+	Equals any
+	// Regex matches the attribute's string representation against a regular expression.
+	Regex string
+	// Contains checks that the attribute's string representation contains a substring.
+	Contains string
+	// IsSensitive asserts the attribute is marked sensitive in the plan, regardless of value.
+	IsSensitive bool
+	// IsNull asserts the attribute is absent or explicitly null.
+	IsNull bool
+}
+
+// Rule ties a single planned-resource attribute to the Matcher it must satisfy.
+type Rule struct {
+	// ResourceAddress is the full address of the resource in the plan, e.g.
+	// `module.alloy_db["dummy"].google_alloydb_cluster.primary`.
+	ResourceAddress string
+	// JSONPath is a dot-separated path into the resource's planned "after"
+	// attributes, e.g. "automated_backup_policy.0.enabled".
+	JSONPath string
+	Matcher  Matcher
+}
+
+// Assert evaluates every Rule against plan and reports a failure with a
+// readable diff for each one that does not hold. It does not stop at the
+// first failure so a single test run surfaces every mismatch.
+func Assert(t TestingT, plan *tfjson.Plan, rules []Rule) {
+	t.Helper()
+	for _, rule := range rules {
+		assertRule(t, plan, rule)
+	}
+}
+
+func assertRule(t TestingT, plan *tfjson.Plan, rule Rule) {
+	t.Helper()
+
+	change, ok := plan.ResourceChangesMap[rule.ResourceAddress]
+	if !ok {
+		t.Errorf("planassert: resource %q not found in plan", rule.ResourceAddress)
+		return
+	}
+	if change.Change == nil {
+		t.Errorf("planassert: resource %q has no planned change", rule.ResourceAddress)
+		return
+	}
+
+	after, _ := change.Change.After.(map[string]any)
+	got, found := lookup(after, rule.JSONPath)
+
+	switch {
+	case rule.Matcher.IsNull:
+		if found && got != nil {
+			t.Errorf("planassert: %s %s = %v, want null", rule.ResourceAddress, rule.JSONPath, got)
+		}
+	case rule.Matcher.IsSensitive:
+		sensitive, _ := change.Change.AfterSensitive.(map[string]any)
+		if val, ok := lookup(sensitive, rule.JSONPath); !ok || val != true {
+			t.Errorf("planassert: %s %s is not marked sensitive", rule.ResourceAddress, rule.JSONPath)
+		}
+	case rule.Matcher.Regex != "":
+		re, err := regexp.Compile(rule.Matcher.Regex)
+		if err != nil {
+			t.Errorf("planassert: invalid regex %q: %v", rule.Matcher.Regex, err)
+			return
+		}
+		if !found || !re.MatchString(fmt.Sprint(got)) {
+			t.Errorf("planassert: %s %s = %v, want match for regex %q", rule.ResourceAddress, rule.JSONPath, got, rule.Matcher.Regex)
+		}
+	case rule.Matcher.Contains != "":
+		if !found || !strings.Contains(fmt.Sprint(got), rule.Matcher.Contains) {
+			t.Errorf("planassert: %s %s = %v, want to contain %q", rule.ResourceAddress, rule.JSONPath, got, rule.Matcher.Contains)
+		}
+	default:
+		if !found {
+			t.Errorf("planassert: %s %s not found, want %v", rule.ResourceAddress, rule.JSONPath, rule.Matcher.Equals)
+			return
+		}
+		if diff := cmp.Diff(rule.Matcher.Equals, got); diff != "" {
+			t.Errorf("planassert: %s %s mismatch (-want +got):\n%s", rule.ResourceAddress, rule.JSONPath, diff)
+		}
+	}
+}
+
+// lookup walks a dot-separated path (list indices are numeric segments, e.g.
+// "initial_user.0.password") through nested maps and slices decoded from
+// plan JSON.
+func lookup(value any, path string) (any, bool) {
+	current := value
+	for _, segment := range strings.Split(path, ".") {
+		if current == nil {
+			return nil, false
+		}
+		if idx, err := strconv.Atoi(segment); err == nil {
+			list, ok := current.([]any)
+			if !ok || idx < 0 || idx >= len(list) {
+				return nil, false
+			}
+			current = list[idx]
+			continue
+		}
+		m, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		current, ok = m[segment]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
@@ -0,0 +1,136 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package planrun lets producer unit test files in the same directory run
+// their plans in parallel instead of serializing on one `terraform init`
+// and one shared `./plan` file. A Suite runs `terraform init` once against
+// the source module with a shared provider plugin cache, then hands every
+// subtest its own temp-dir copy of the module so PlanFilePath and the
+// .terraform directory never collide.
+package planrun
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/terraform"
+)
+
+// Suite centralizes the one-time init for every test that plans SourceDir.
+type Suite struct {
+	SourceDir string
+	CacheDir  string
+}
+
+// NewSuite creates a Suite for sourceDir with a fresh provider plugin cache
+// directory. Call InitOnce from TestMain before m.Run(), then Options from
+// each test to get its own copy of the module to plan against.
+func NewSuite(sourceDir string) (*Suite, error) {
+	cacheDir, err := os.MkdirTemp("", "cncs-plugin-cache-")
+	if err != nil {
+		return nil, err
+	}
+	return &Suite{SourceDir: sourceDir, CacheDir: cacheDir}, nil
+}
+
+// InitOnce sets TF_PLUGIN_CACHE_DIR for the whole process and runs
+// `terraform init` against SourceDir a single time, populating CacheDir so
+// every per-test copy's own init is a cache hit rather than a provider
+// re-download. It has no *testing.T to report through because TestMain only
+// has a *testing.M, so it panics on failure like other TestMain setup steps
+// do.
+//
+// The cache dir is process-wide (via os.Setenv, not t.Setenv) because its
+// value is the same for every test; setting it per-test would require
+// t.Setenv, which panics once a test has called t.Parallel().
+func (s *Suite) InitOnce() {
+	if err := os.Setenv("TF_PLUGIN_CACHE_DIR", s.CacheDir); err != nil {
+		panic("planrun: failed to set TF_PLUGIN_CACHE_DIR: " + err.Error())
+	}
+
+	cmd := exec.Command("terraform", "init", "-input=false")
+	cmd.Dir = s.SourceDir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		panic("planrun: terraform init failed: " + err.Error() + "\n" + string(out))
+	}
+}
+
+// Options copies SourceDir into a fresh t.TempDir(), reusing CacheDir so its
+// `terraform init` is fast, and returns terraform.Options pointed at that
+// copy with vars and a collision-free PlanFilePath. The test is free to call
+// t.Parallel() since no state is shared with any other test's Options.
+//
+// The returned buffer accumulates every terraform command's combined
+// stdout/stderr for the lifetime of the test (terratest writes through
+// Options.Logger), so a failing test can attach it to a junit.Track failure.
+func (s *Suite) Options(t *testing.T, vars map[string]any) (*terraform.Options, *bytes.Buffer) {
+	t.Helper()
+
+	dir := t.TempDir()
+	if err := copyDir(s.SourceDir, dir); err != nil {
+		t.Fatalf("planrun: failed to copy %s to %s: %v", s.SourceDir, dir, err)
+	}
+
+	var output bytes.Buffer
+	opts := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
+		TerraformDir: dir,
+		Vars:         vars,
+		Reconfigure:  true,
+		Lock:         true,
+		PlanFilePath: filepath.Join(dir, "plan"),
+		NoColor:      true,
+		Logger:       logger.New(log.New(&output, "", 0)),
+	})
+	return opts, &output
+}
+
+func copyDir(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0o755)
+		}
+		return copyFile(path, target)
+	})
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, in)
+	return err
+}
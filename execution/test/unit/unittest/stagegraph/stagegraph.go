@@ -0,0 +1,121 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package stagegraph validates that a stage's plan JSON still exposes the
+// outputs a downstream stage's plan JSON expects as input variables, so a
+// producer renaming an output doesn't silently break a consumer that still
+// references the old name. The dependency graph itself lives in a small
+// YAML manifest rather than in code, so adding a new stage to the DAG
+// doesn't require touching this package.
+package stagegraph
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"gopkg.in/yaml.v3"
+)
+
+// Stage names one entry in the DAG and the directory its plan was produced from.
+type Stage struct {
+	Name         string `yaml:"name"`
+	TerraformDir string `yaml:"terraform_dir"`
+}
+
+// Binding asserts that FromStage's FromOutput feeds ToStage's ToVariable.
+type Binding struct {
+	FromStage  string `yaml:"from_stage"`
+	FromOutput string `yaml:"from_output"`
+	ToStage    string `yaml:"to_stage"`
+	ToVariable string `yaml:"to_variable"`
+}
+
+// Manifest is the DAG of stages and the output-to-variable bindings between them.
+type Manifest struct {
+	Stages   []Stage   `yaml:"stages"`
+	Bindings []Binding `yaml:"bindings"`
+}
+
+// LoadManifest reads and parses a stage-dependency manifest from path.
+func LoadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var manifest Manifest
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("stagegraph: failed to parse manifest %s: %w", path, err)
+	}
+	return &manifest, nil
+}
+
+// Validate checks every Binding in manifest against plans, a map of stage
+// name to that stage's parsed plan JSON. For each binding it confirms the
+// upstream output still exists, the downstream variable still exists, and
+// reports a mismatch if the output's planned value and the variable's
+// resolved value in that plan run are both known and differ.
+func Validate(t *testing.T, plans map[string]*tfjson.Plan, manifest *Manifest) {
+	t.Helper()
+
+	for _, binding := range manifest.Bindings {
+		fromPlan, ok := plans[binding.FromStage]
+		if !ok {
+			t.Errorf("stagegraph: no plan captured for stage %q (from %s.%s)", binding.FromStage, binding.FromStage, binding.FromOutput)
+			continue
+		}
+		toPlan, ok := plans[binding.ToStage]
+		if !ok {
+			t.Errorf("stagegraph: no plan captured for stage %q (to %s.%s)", binding.ToStage, binding.ToStage, binding.ToVariable)
+			continue
+		}
+
+		outputChange, ok := fromPlan.OutputChanges[binding.FromOutput]
+		if !ok {
+			t.Errorf("stagegraph: stage %q no longer has an output named %q, but %q still consumes it as %q",
+				binding.FromStage, binding.FromOutput, binding.ToStage, binding.ToVariable)
+			continue
+		}
+
+		if _, ok := rootVariable(toPlan, binding.ToVariable); !ok {
+			t.Errorf("stagegraph: stage %q no longer declares a variable named %q, fed by %s.%s",
+				binding.ToStage, binding.ToVariable, binding.FromStage, binding.FromOutput)
+			continue
+		}
+
+		// Compare against the variable's actual resolved value for this plan
+		// run, not its static declared default: a wired-through variable like
+		// alloydb_cluster_connection_name normally has no default at all, so
+		// comparing defaults would never catch a real mismatch.
+		resolved, ok := toPlan.Variables[binding.ToVariable]
+		if ok && resolved.Value != nil && outputChange.After != nil && !equalValue(resolved.Value, outputChange.After) {
+			t.Errorf("stagegraph: %s.%s = %v, but %s.%s = %v",
+				binding.FromStage, binding.FromOutput, outputChange.After,
+				binding.ToStage, binding.ToVariable, resolved.Value)
+		}
+	}
+}
+
+func rootVariable(plan *tfjson.Plan, name string) (*tfjson.ConfigVariable, bool) {
+	if plan.Config == nil || plan.Config.RootModule == nil {
+		return nil, false
+	}
+	variable, ok := plan.Config.RootModule.Variables[name]
+	return variable, ok
+}
+
+func equalValue(a, b any) bool {
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}